@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/LynnT-2003/GoSanity-MV-Realty-Backend/sanityimage"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// API wires HTTP handlers to their dependencies: the cached property store
+// for fast/default reads, the Sanity endpoint for live, filter-specific
+// GROQ queries, and the resolver for on-demand CDN image transforms.
+type API struct {
+	store     *PropertyStore
+	sanityAPI *SanityEndpoint
+	resolver  *ImageResolver
+}
+
+// NewAPI returns an API ready to be registered on a router.
+func NewAPI(store *PropertyStore, sanityAPI *SanityEndpoint, resolver *ImageResolver) *API {
+	return &API{store: store, sanityAPI: sanityAPI, resolver: resolver}
+}
+
+// GetProperties serves /properties. With no filter or pagination
+// parameters it serves the cached snapshot as before, honoring
+// If-None-Match/ETag. Any filter parameter triggers a live GROQ query
+// against Sanity, so filtering happens server-side instead of over the full
+// in-memory slice; pagination without a filter pages the cached snapshot.
+// X-Total-Count reports the match count before pagination is applied.
+func (a *API) GetProperties(w http.ResponseWriter, r *http.Request) {
+	filter, err := parsePropertyFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if filter.IsEmpty() && filter.PageSize == 0 {
+		a.store.GetProperties(w, r)
+		return
+	}
+
+	var properties []Property
+	if filter.IsEmpty() {
+		properties, _ = a.store.Snapshot()
+	} else if properties, err = queryPropertiesFromSanity(r.Context(), a.sanityAPI.Get(), filter, a.resolver); err != nil {
+		log.Error().Err(err).Msg("Live Sanity query failed, falling back to cached snapshot")
+		cached, _ := a.store.Snapshot()
+		properties = filterProperties(cached, filter)
+	}
+
+	total := len(properties)
+	page := paginate(properties, filter.Page, filter.PageSize)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	json.NewEncoder(w).Encode(page)
+}
+
+// GetNearby serves /properties/nearby?lat=&lng=&radiusKm=, returning
+// properties within radiusKm of the given point, nearest first. Geo-radius
+// search has no direct GROQ equivalent for this schema, so it filters the
+// cached snapshot in-memory via haversineKm.
+func (a *API) GetNearby(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil {
+		http.Error(w, "invalid or missing lat", http.StatusBadRequest)
+		return
+	}
+	lng, err := strconv.ParseFloat(q.Get("lng"), 64)
+	if err != nil {
+		http.Error(w, "invalid or missing lng", http.StatusBadRequest)
+		return
+	}
+	radiusKm, err := strconv.ParseFloat(q.Get("radiusKm"), 64)
+	if err != nil {
+		http.Error(w, "invalid or missing radiusKm", http.StatusBadRequest)
+		return
+	}
+
+	properties, _ := a.store.Snapshot()
+	distance := make(map[string]float64, len(properties))
+	nearby := make([]Property, 0, len(properties))
+	for _, p := range properties {
+		d := haversineKm(lat, lng, p.GeoLocation.Lat, p.GeoLocation.Lng)
+		if d <= radiusKm {
+			distance[p.ID] = d
+			nearby = append(nearby, p)
+		}
+	}
+	sort.Slice(nearby, func(i, j int) bool {
+		return distance[nearby[i].ID] < distance[nearby[j].ID]
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(nearby)))
+	json.NewEncoder(w).Encode(nearby)
+}
+
+// GetPhoto serves /properties/{slug}/photo/{key}, redirecting to a Sanity
+// CDN URL for the matching photo. Query parameters w, h, fit, and q map
+// directly onto Sanity's image transform pipeline.
+func (a *API) GetPhoto(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	property, ok := a.store.BySlug(params["slug"])
+	if !ok {
+		http.Error(w, "Property not found", http.StatusNotFound)
+		return
+	}
+
+	var photo *SanityImage
+	for i := range property.Photos {
+		if property.Photos[i].Key == params["key"] {
+			photo = &property.Photos[i]
+			break
+		}
+	}
+	if photo == nil {
+		http.Error(w, "Photo not found", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	opts := sanityimage.TransformOptions{Auto: "format", Fit: q.Get("fit")}
+	if v, err := strconv.Atoi(q.Get("w")); err == nil {
+		opts.Width = v
+	}
+	if v, err := strconv.Atoi(q.Get("h")); err == nil {
+		opts.Height = v
+	}
+	if v, err := strconv.Atoi(q.Get("q")); err == nil {
+		opts.Quality = v
+	}
+
+	asset := sanityimage.Asset{Ref: photo.Asset.Ref, Type: photo.Asset.Type}
+	target := sanityimage.ResolveURL(a.resolver.project, a.resolver.dataset, asset, opts)
+	if target == "" {
+		http.Error(w, "Photo asset reference is not a resolvable Sanity image", http.StatusUnprocessableEntity)
+		return
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+}
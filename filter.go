@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PropertyFilter captures the query-parameter filters and pagination
+// accepted by GET /properties. A zero value means "no constraint".
+type PropertyFilter struct {
+	MinPrice   *float64
+	MaxPrice   *float64
+	Developer  string
+	BuiltAfter *int
+	Facilities []string
+	Page       int
+	PageSize   int
+}
+
+// parsePropertyFilter reads filter and pagination parameters from the
+// request's query string.
+func parsePropertyFilter(r *http.Request) (PropertyFilter, error) {
+	q := r.URL.Query()
+	filter := PropertyFilter{Page: 1}
+
+	if v := q.Get("minPrice"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid minPrice %q: %w", v, err)
+		}
+		filter.MinPrice = &f
+	}
+	if v := q.Get("maxPrice"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid maxPrice %q: %w", v, err)
+		}
+		filter.MaxPrice = &f
+	}
+	filter.Developer = q.Get("developer")
+	if v := q.Get("builtAfter"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid builtAfter %q: %w", v, err)
+		}
+		filter.BuiltAfter = &y
+	}
+	filter.Facilities = q["facility"]
+
+	if v := q.Get("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 {
+			return filter, fmt.Errorf("invalid page %q", v)
+		}
+		filter.Page = p
+	}
+	if v := q.Get("pageSize"); v != "" {
+		ps, err := strconv.Atoi(v)
+		if err != nil || ps < 1 {
+			return filter, fmt.Errorf("invalid pageSize %q", v)
+		}
+		filter.PageSize = ps
+	}
+
+	return filter, nil
+}
+
+// IsEmpty reports whether the filter leaves the result set unconstrained,
+// i.e. whether the cached snapshot can be served instead of making a live
+// GROQ query. Pagination fields are not considered.
+func (f PropertyFilter) IsEmpty() bool {
+	return f.MinPrice == nil && f.MaxPrice == nil && f.Developer == "" &&
+		f.BuiltAfter == nil && len(f.Facilities) == 0
+}
+
+// GROQPredicate renders the filter as additional GROQ `&&` clauses meant to
+// be spliced inside the base query's brackets (e.g.
+// `*[_type == "property"` + f.GROQPredicate() + `]`), so Sanity filters the
+// result set instead of the server fetching everything and filtering
+// in-memory.
+func (f PropertyFilter) GROQPredicate() string {
+	var clauses []string
+	if f.MinPrice != nil {
+		clauses = append(clauses, fmt.Sprintf("minPrice >= %s", strconv.FormatFloat(*f.MinPrice, 'f', -1, 64)))
+	}
+	if f.MaxPrice != nil {
+		clauses = append(clauses, fmt.Sprintf("maxPrice <= %s", strconv.FormatFloat(*f.MaxPrice, 'f', -1, 64)))
+	}
+	if f.Developer != "" {
+		clauses = append(clauses, fmt.Sprintf("developer._ref == %q", f.Developer))
+	}
+	if f.BuiltAfter != nil {
+		clauses = append(clauses, fmt.Sprintf("built > %d", *f.BuiltAfter))
+	}
+	for _, facility := range f.Facilities {
+		clauses = append(clauses, fmt.Sprintf("%q in facilities[].facilityName", facility))
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return " && " + strings.Join(clauses, " && ")
+}
+
+// Matches reports whether property satisfies f. Used to filter the cached
+// snapshot when a live Sanity query isn't available (e.g. as a fallback
+// on query error).
+func (f PropertyFilter) Matches(p Property) bool {
+	if f.MinPrice != nil && float64(p.MinPrice) < *f.MinPrice {
+		return false
+	}
+	if f.MaxPrice != nil && float64(p.MaxPrice) > *f.MaxPrice {
+		return false
+	}
+	if f.Developer != "" && p.Developer.Ref != f.Developer {
+		return false
+	}
+	if f.BuiltAfter != nil && p.Built <= *f.BuiltAfter {
+		return false
+	}
+	for _, want := range f.Facilities {
+		found := false
+		for _, facility := range p.Facilities {
+			if facility.FacilityName == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// filterProperties returns the subset of properties matching filter.
+func filterProperties(properties []Property, filter PropertyFilter) []Property {
+	out := make([]Property, 0, len(properties))
+	for _, p := range properties {
+		if filter.Matches(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// paginate slices properties to the requested page. pageSize <= 0 means
+// pagination wasn't requested, so the full slice is returned unchanged.
+func paginate(properties []Property, page, pageSize int) []Property {
+	if pageSize <= 0 {
+		return properties
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	start := (page - 1) * pageSize
+	if start >= len(properties) {
+		return []Property{}
+	}
+
+	end := start + pageSize
+	if end > len(properties) {
+		end = len(properties)
+	}
+	return properties[start:end]
+}
@@ -0,0 +1,103 @@
+package main
+
+import "testing"
+
+func TestGROQPredicate(t *testing.T) {
+	minPrice := 100.0
+	maxPrice := 500.0
+	builtAfter := 2010
+
+	cases := []struct {
+		name   string
+		filter PropertyFilter
+		want   string
+	}{
+		{
+			name:   "empty filter produces no predicate",
+			filter: PropertyFilter{},
+			want:   "",
+		},
+		{
+			name:   "single clause",
+			filter: PropertyFilter{MinPrice: &minPrice},
+			want:   " && minPrice >= 100",
+		},
+		{
+			name: "multiple clauses joined with &&",
+			filter: PropertyFilter{
+				MinPrice:   &minPrice,
+				MaxPrice:   &maxPrice,
+				Developer:  "developer-1",
+				BuiltAfter: &builtAfter,
+				Facilities: []string{"Pool"},
+			},
+			want: ` && minPrice >= 100 && maxPrice <= 500 && developer._ref == "developer-1" && built > 2010 && "Pool" in facilities[].facilityName`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.GROQPredicate(); got != tc.want {
+				t.Errorf("GROQPredicate() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestAssembledGROQQuery guards against the predicate being spliced outside
+// the base query's brackets, which produces invalid GROQ like
+// `*[_type == "property"] && minPrice >= 100` instead of
+// `*[_type == "property" && minPrice >= 100]`.
+func TestAssembledGROQQuery(t *testing.T) {
+	minPrice := 100.0
+	filter := PropertyFilter{MinPrice: &minPrice}
+
+	query := `*[_type == "property"` + filter.GROQPredicate() + `]`
+	want := `*[_type == "property" && minPrice >= 100]`
+	if query != want {
+		t.Errorf("assembled query = %q, want %q", query, want)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	minPrice := 200.0
+	property := Property{
+		MinPrice:  100,
+		MaxPrice:  300,
+		Developer: Reference{Ref: "developer-1"},
+		Built:     2015,
+		Facilities: []Facility{
+			{FacilityName: "Pool"},
+		},
+	}
+
+	if (PropertyFilter{MinPrice: &minPrice}).Matches(property) {
+		t.Error("Matches() = true, want false: property.MinPrice is below filter.MinPrice")
+	}
+	if !(PropertyFilter{Developer: "developer-1"}).Matches(property) {
+		t.Error("Matches() = false, want true: developer matches")
+	}
+	if (PropertyFilter{Facilities: []string{"Gym"}}).Matches(property) {
+		t.Error("Matches() = true, want false: property has no Gym facility")
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	properties := make([]Property, 5)
+	for i := range properties {
+		properties[i] = Property{ID: string(rune('a' + i))}
+	}
+
+	if got := paginate(properties, 1, 0); len(got) != 5 {
+		t.Errorf("pageSize 0 should return all properties, got %d", len(got))
+	}
+	if got := paginate(properties, 1, 2); len(got) != 2 || got[0].ID != "a" {
+		t.Errorf("page 1 size 2 = %v, want [a b]", got)
+	}
+	if got := paginate(properties, 3, 2); len(got) != 1 || got[0].ID != "e" {
+		t.Errorf("page 3 size 2 = %v, want [e]", got)
+	}
+	if got := paginate(properties, 10, 2); len(got) != 0 {
+		t.Errorf("out-of-range page should return empty, got %v", got)
+	}
+}
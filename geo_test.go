@@ -0,0 +1,29 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineKm(t *testing.T) {
+	cases := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+		tolerance              float64
+	}{
+		{name: "same point", lat1: 1.35, lng1: 103.82, lat2: 1.35, lng2: 103.82, want: 0, tolerance: 0.001},
+		// Singapore to Kuala Lumpur, ~309km.
+		{name: "singapore to kuala lumpur", lat1: 1.3521, lng1: 103.8198, lat2: 3.1390, lng2: 101.6869, want: 309.25, tolerance: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := haversineKm(tc.lat1, tc.lng1, tc.lat2, tc.lng2)
+			if math.Abs(got-tc.want) > tc.tolerance {
+				t.Errorf("haversineKm(%v, %v, %v, %v) = %v, want ~%v (+/- %v)",
+					tc.lat1, tc.lng1, tc.lat2, tc.lng2, got, tc.want, tc.tolerance)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/LynnT-2003/GoSanity-MV-Realty-Backend/sanityimage"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultSrcSetWidths are the breakpoints used to build each SanityImage's
+// srcSet, chosen to cover common thumbnail/card/hero layouts.
+var defaultSrcSetWidths = []int{400, 800, 1200, 1600}
+
+// ImageResolver fills in each SanityImage's derived url, srcSet, and lqip
+// fields so frontends don't need to know Sanity's CDN URL format.
+type ImageResolver struct {
+	project string
+	dataset string
+	cache   *sanityimage.Cache
+}
+
+// NewImageResolver returns a resolver for the given Sanity project/dataset.
+// An empty project or dataset disables resolution: Enrich becomes a no-op
+// and GetPhoto responds with 422, rather than producing broken CDN URLs.
+func NewImageResolver(project, dataset string) *ImageResolver {
+	return &ImageResolver{
+		project: project,
+		dataset: dataset,
+		cache:   sanityimage.NewCache(http.DefaultClient),
+	}
+}
+
+// Enrich fills in url/srcSet/lqip on every photo across properties in
+// place and returns the same slice for convenience. LQIP fetches are
+// best-effort: a failure is logged and leaves that image's lqip empty
+// rather than failing the whole batch.
+func (ir *ImageResolver) Enrich(ctx context.Context, properties []Property) []Property {
+	if ir.project == "" || ir.dataset == "" {
+		return properties
+	}
+
+	for i := range properties {
+		ir.enrichPhotos(ctx, properties[i].Photos)
+		for j := range properties[i].Facilities {
+			ir.enrichPhotos(ctx, properties[i].Facilities[j].Photos)
+		}
+	}
+	return properties
+}
+
+func (ir *ImageResolver) enrichPhotos(ctx context.Context, photos []SanityImage) {
+	for i := range photos {
+		asset := sanityimage.Asset{Ref: photos[i].Asset.Ref, Type: photos[i].Asset.Type}
+		opts := sanityimage.TransformOptions{Auto: "format"}
+
+		photos[i].URL = sanityimage.ResolveURL(ir.project, ir.dataset, asset, opts)
+		photos[i].SrcSet = sanityimage.SrcSet(ir.project, ir.dataset, asset, opts, defaultSrcSetWidths)
+
+		lqip, err := ir.cache.LQIP(ctx, ir.project, ir.dataset, asset)
+		if err != nil {
+			log.Warn().Err(err).Str("ref", asset.Ref).Msg("Failed to build LQIP placeholder")
+			continue
+		}
+		photos[i].LQIP = lqip
+	}
+}
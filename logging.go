@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// init configures the global zerolog logger to emit JSON lines to stdout
+// with a Unix timestamp, replacing the stdlib log package's plain-text
+// output used throughout the rest of the service.
+func init() {
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	log.Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+}
@@ -1,18 +1,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"log"
 	"net/http"
-	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
 )
 
 // Property struct
@@ -59,6 +60,13 @@ type SanityImage struct {
 	Key   string `json:"_key"`
 	Type  string `json:"_type"`
 	Asset Asset  `json:"asset"`
+
+	// URL, SrcSet, and LQIP are derived from Asset by an ImageResolver
+	// after fetching from Sanity; they're absent from Sanity's own
+	// response, so omitempty keeps them out of the JSON until resolved.
+	URL    string `json:"url,omitempty"`
+	SrcSet string `json:"srcSet,omitempty"`
+	LQIP   string `json:"lqip,omitempty"`
 }
 
 type Asset struct {
@@ -66,133 +74,214 @@ type Asset struct {
 	Type string `json:"_type"`
 }
 
-// Mock data for now (later, you'll fetch this from Sanity)
-var properties []Property
-
 // const sanityAPI = "https://tq4u5fnu.api.sanity.io/v1/data/query/production?query="
 
+const (
+	minBackoff = 5 * time.Second
+	maxBackoff = 1 * time.Hour
+
+	// safetyNetPollInterval is the fallback poll cadence once the Sanity
+	// webhook handles push-based invalidation; it only matters if a
+	// webhook delivery is missed.
+	safetyNetPollInterval = 6 * time.Hour
+)
+
 func main() {
 
 	// uncomment here for localhost testing
     err := godotenv.Load()
     if err != nil {
-        log.Fatal("Error loading .env file:", err)
+        log.Fatal().Err(err).Msg("Error loading .env file")
     }
 
 	// Fetch SANITY_API_URL from environment variables
 	sanityAPI := os.Getenv("SANITY_API_URL")
 	if sanityAPI == "" {
-		log.Fatal("SANITY_API_URL is not set in the environment")
+		log.Fatal().Msg("SANITY_API_URL is not set in the environment")
 	}
 
 	// Initialize mux router
 	r := mux.NewRouter()
 
+	// Registered via r.Use (not wrapped around the router) so metricsMiddleware
+	// runs after route matching and can read the matched route template from
+	// mux.CurrentRoute.
+	r.Use(requestIDMiddleware, metricsMiddleware, loggingMiddleware)
+
 	cors := handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}), // Allow requests from all origins
 		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
 		handlers.AllowedHeaders([]string{"Content-Type", "X-API-Key"}),
 	)
 
-	// Create a new handler with CORS middleware
-	handler := cors(r)
+	project, dataset, err := parseProjectDataset(sanityAPI)
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not derive Sanity project/dataset from SANITY_API_URL; image URL resolution is disabled")
+	}
+	resolver := NewImageResolver(project, dataset)
 
+	// endpoint is the shared, mutex-guarded source of truth for the current
+	// SANITY_API_URL: both the background fetch loop and live filtered
+	// queries read it, so a SIGHUP reload (below) is visible to both
+	// instead of only whichever one captured sanityAPI at startup.
+	endpoint := NewSanityEndpoint(sanityAPI)
 
-	// Route handles & endpoints
-	r.HandleFunc("/properties", GetProperties).Methods("GET")
-	r.HandleFunc("/properties/{slug}", GetPropertyBySlug).Methods("GET")
+	store := NewPropertyStore()
+	api := NewAPI(store, endpoint, resolver)
 
-	// Start fetching properties from Sanity every hour
-	go func() {
-		for {
-			log.Println("Starting property fetch from Sanity...")
-			start := time.Now()
+	// refetch lets a SIGHUP handler or the Sanity webhook nudge the fetch
+	// loop into an immediate run instead of waiting out the rest of its
+	// poll interval.
+	refetch := make(chan string, 1)
+
+	webhookSecret := os.Getenv("SANITY_WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		log.Warn().Msg("SANITY_WEBHOOK_SECRET is not set; /webhooks/sanity will reject all requests")
+	}
+	webhook := NewWebhookHandler(webhookSecret, refetch)
 
-			fetchPropertiesFromSanity(sanityAPI)
+	// Route handles & endpoints
+	r.HandleFunc("/properties", api.GetProperties).Methods("GET")
+	r.HandleFunc("/properties/nearby", api.GetNearby).Methods("GET")
+	r.HandleFunc("/properties/{slug}", store.GetPropertyBySlug).Methods("GET")
+	r.HandleFunc("/properties/{slug}/photo/{key}", api.GetPhoto).Methods("GET")
+	r.HandleFunc("/healthz", store.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", store.Readyz).Methods("GET")
+	r.Handle("/webhooks/sanity", webhook).Methods("POST")
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
-			log.Printf("Property fetch completed in %s", time.Since(start))
-			log.Println("Next update will occur in 1 hour.")
+	// Create a new handler with CORS middleware
+	handler := cors(r)
 
-			time.Sleep(1 * time.Hour)
-		}
-	}()
+	// ctx is cancelled on SIGINT/SIGTERM and threaded into the background
+	// fetch loop so an in-flight Sanity request or backoff sleep is
+	// abandoned instead of leaking past shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Start server
-	// log.Println("Server is running on :8000")
-	// log.Fatal(http.ListenAndServe(":8000", handler))
+	go runFetchLoop(ctx, store, endpoint, refetch, resolver)
 
-	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8000" // Default port to 8000 if PORT environment variable is not set
 	}
-	fmt.Println("Server is running on port:", port)
-	log.Fatal(http.ListenAndServe(":"+port, handler))
-}
 
-// GetProperties returns all properties
-func GetProperties(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(properties)
-}
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: handler,
+	}
 
-// fetchPropertiesFromSanity fetches properties from Sanity and updates the in-memory `properties` slice
-func fetchPropertiesFromSanity(sanityAPI string) {
-	query := "*[_type == \"property\"]"
-	encodedQuery := url.QueryEscape(query)
-	url := sanityAPI + encodedQuery
+	go func() {
+		log.Info().Str("port", port).Msg("Server is running")
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("HTTP server error")
+		}
+	}()
 
-	resp, err := http.Get(url)
-	if err != nil {
-		log.Println("Failed to fetch properties from Sanity:", err)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	for sig := range sigCh {
+		if sig == syscall.SIGHUP {
+			log.Info().Msg("Received SIGHUP, reloading .env and triggering refetch...")
+			if err := godotenv.Overload(); err != nil {
+				log.Error().Err(err).Msg("Failed to reload .env file")
+			}
+			if newAPI := os.Getenv("SANITY_API_URL"); newAPI != "" {
+				endpoint.Set(newAPI)
+			}
+			select {
+			case refetch <- "":
+			default:
+				// a refetch is already queued; no need to pile up another
+			}
+			continue
+		}
+
+		log.Info().Msg("Received shutdown signal, draining connections...")
+		cancel()
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("Graceful shutdown failed")
+		}
+		shutdownCancel()
 		return
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Println("Sanity API returned non-200 status:", resp.Status)
-		return
+// runFetchLoop periodically refreshes store from Sanity until ctx is
+// cancelled. It wakes early whenever refetch fires (a SIGHUP reload or a
+// verified Sanity webhook), and backs off exponentially between retries on
+// error instead of always waiting out the full safety-net interval. It reads
+// endpoint.Get() on every iteration so a SIGHUP reload takes effect on the
+// very next fetch instead of requiring a restart.
+func runFetchLoop(ctx context.Context, store *PropertyStore, endpoint *SanityEndpoint, refetch <-chan string, resolver *ImageResolver) {
+	backoff := minBackoff
+	wait := safetyNetPollInterval
+
+	for {
+		log.Info().Msg("Starting property fetch from Sanity...")
+		start := time.Now()
+
+		err := fetchPropertiesFromSanity(ctx, store, endpoint.Get(), resolver)
+		observeSanityFetch(time.Since(start), err)
+
+		if err != nil {
+			store.SetError(err)
+			log.Error().Err(err).Dur("elapsed", time.Since(start)).Msg("Property fetch failed")
+			log.Info().Dur("retry_in", backoff).Msg("Scheduling retry")
+			wait = backoff
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		} else {
+			backoff = minBackoff
+			wait = safetyNetPollInterval
+			log.Info().Dur("elapsed", time.Since(start)).Msg("Property fetch completed")
+			log.Info().Dur("poll_interval", safetyNetPollInterval).Msg("Next safety-net poll scheduled")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Err(ctx.Err()).Msg("Fetch loop stopped")
+			return
+		case <-refetch:
+			log.Info().Msg("Immediate refetch triggered")
+		case <-time.After(wait):
+		}
 	}
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Println("Failed to read Sanity API response:", err)
-		return
+// GetProperties returns the current snapshot, honoring If-None-Match and
+// advertising freshness via ETag/Last-Modified so clients can cache it.
+func (s *PropertyStore) GetProperties(w http.ResponseWriter, r *http.Request) {
+	properties, etag := s.Snapshot()
+
+	w.Header().Set("Content-Type", "application/json")
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	status := s.Status()
+	if !status.LastFetched.IsZero() {
+		w.Header().Set("Last-Modified", status.LastFetched.UTC().Format(http.TimeFormat))
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		log.Println("Failed to parse JSON from Sanity API:", err)
+	if etag != "" && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
-	if propertiesData, ok := result["result"].([]interface{}); ok {
-		newProperties := []Property{}
-		for _, propertyData := range propertiesData {
-			propertyBytes, _ := json.Marshal(propertyData)
-			var property Property
-			if err := json.Unmarshal(propertyBytes, &property); err != nil {
-				log.Println("Failed to unmarshal property:", err)
-				continue
-			}
-			newProperties = append(newProperties, property)
-		}
-		properties = newProperties
-		log.Println("Properties successfully updated from Sanity.")
-	} else {
-		log.Println("No properties found in Sanity API response.")
-	}
+	json.NewEncoder(w).Encode(properties)
 }
 
 // GetPropertyBySlug returns a single property by slug
-func GetPropertyBySlug(w http.ResponseWriter, r *http.Request) {
+func (s *PropertyStore) GetPropertyBySlug(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	for _, item := range properties {
-		if item.Slug.Current == params["slug"] {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(item)
-			return
-		}
+	if item, ok := s.BySlug(params["slug"]); ok {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(item)
+		return
 	}
 	http.Error(w, "Property not found", http.StatusNotFound)
 }
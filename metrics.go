@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total HTTP requests by route, method, and status.",
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	httpRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by route and method.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"route", "method"},
+	)
+
+	sanityFetchSuccessTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sanity_fetch_success_total",
+		Help: "Count of successful background Sanity property fetches.",
+	})
+
+	sanityFetchFailureTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sanity_fetch_failure_total",
+		Help: "Count of failed background Sanity property fetches.",
+	})
+
+	sanityFetchDurationSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sanity_fetch_duration_seconds",
+		Help: "Duration of the most recently completed Sanity property fetch, in seconds.",
+	})
+)
+
+// observeSanityFetch records the outcome of a single fetchPropertiesFromSanity
+// call so /metrics reflects background-fetch health alongside HTTP traffic.
+func observeSanityFetch(duration time.Duration, err error) {
+	sanityFetchDurationSeconds.Set(duration.Seconds())
+	if err != nil {
+		sanityFetchFailureTotal.Inc()
+		return
+	}
+	sanityFetchSuccessTotal.Inc()
+}
+
+// metricsMiddleware records per-route request counts and latency, keyed by
+// the matched mux route template (not the raw path) so templated routes
+// like /properties/{slug} don't blow up label cardinality.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		route := routeTemplate(r)
+		httpRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		httpRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// unmatchedRouteLabel is the route label used when no mux route matched
+// (e.g. a 404). Falling back to the raw request path would let untrusted
+// input (bot scans, typos, path traversal probes) create an unbounded
+// number of distinct label values.
+const unmatchedRouteLabel = "unmatched"
+
+// routeTemplate returns the matched mux route's path template, falling back
+// to unmatchedRouteLabel if no route matched.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return unmatchedRouteLabel
+}
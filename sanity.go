@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SanityEndpoint holds the configured SANITY_API_URL behind a mutex, same
+// pattern as PropertyStore, so a SIGHUP config reload is visible to every
+// consumer (the background fetch loop and live filtered queries issued
+// through API) instead of only whichever one captured it at startup.
+type SanityEndpoint struct {
+	mu  sync.RWMutex
+	url string
+}
+
+// NewSanityEndpoint returns an endpoint initialized to url.
+func NewSanityEndpoint(url string) *SanityEndpoint {
+	return &SanityEndpoint{url: url}
+}
+
+// Get returns the current SANITY_API_URL.
+func (e *SanityEndpoint) Get() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.url
+}
+
+// Set updates the current SANITY_API_URL, e.g. on a SIGHUP config reload.
+func (e *SanityEndpoint) Set(url string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.url = url
+}
+
+// fetchPropertiesFromSanity fetches the full property list from Sanity and
+// updates store with the new snapshot. The request is bound to ctx so a
+// shutdown can abort it mid-flight. The previous snapshot
+// in store is left untouched on error so handlers keep serving the last
+// known-good data.
+func fetchPropertiesFromSanity(ctx context.Context, store *PropertyStore, sanityAPI string, resolver *ImageResolver) error {
+	properties, err := queryPropertiesFromSanity(ctx, sanityAPI, PropertyFilter{}, resolver)
+	if err != nil {
+		return err
+	}
+
+	store.Set(properties)
+	log.Info().Msg("Properties successfully updated from Sanity")
+	return nil
+}
+
+// queryPropertiesFromSanity runs a GROQ query against Sanity narrowed by
+// filter's predicate, so filtering happens server-side instead of fetching
+// every property and filtering in-memory, then enriches each photo via
+// resolver before returning.
+func queryPropertiesFromSanity(ctx context.Context, sanityAPI string, filter PropertyFilter, resolver *ImageResolver) ([]Property, error) {
+	query := `*[_type == "property"` + filter.GROQPredicate() + `]`
+
+	body, err := doSanityQuery(ctx, sanityAPI, query)
+	if err != nil {
+		return nil, err
+	}
+
+	properties, err := parsePropertiesResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.Enrich(ctx, properties), nil
+}
+
+// parseProjectDataset derives the Sanity project ID and dataset from the
+// configured query API URL (e.g.
+// "https://<project>.api.sanity.io/v1/data/query/<dataset>?query="), so
+// image URL resolution doesn't need its own redundant configuration.
+func parseProjectDataset(sanityAPI string) (project, dataset string, err error) {
+	u, err := url.Parse(sanityAPI)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing SANITY_API_URL: %w", err)
+	}
+
+	project = strings.TrimSuffix(u.Hostname(), ".api.sanity.io")
+	if project == u.Hostname() || project == "" {
+		return "", "", fmt.Errorf("SANITY_API_URL host %q is not a Sanity API host", u.Hostname())
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	dataset = segments[len(segments)-1]
+	if dataset == "" {
+		return "", "", fmt.Errorf("SANITY_API_URL path %q has no dataset segment", u.Path)
+	}
+
+	return project, dataset, nil
+}
+
+// doSanityQuery issues a GROQ query against sanityAPI and returns the raw
+// response body.
+func doSanityQuery(ctx context.Context, sanityAPI, query string) ([]byte, error) {
+	reqURL := sanityAPI + url.QueryEscape(query)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building Sanity request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching properties from Sanity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sanity API returned non-200 status: %s", resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parsePropertiesResponse decodes a Sanity GROQ query response into
+// properties, skipping (and logging) any individual document that fails
+// to unmarshal rather than failing the whole batch.
+func parsePropertiesResponse(body []byte) ([]Property, error) {
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parsing JSON from Sanity API: %w", err)
+	}
+
+	propertiesData, ok := result["result"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no properties found in Sanity API response")
+	}
+
+	properties := []Property{}
+	for _, propertyData := range propertiesData {
+		propertyBytes, _ := json.Marshal(propertyData)
+		var property Property
+		if err := json.Unmarshal(propertyBytes, &property); err != nil {
+			log.Error().Err(err).Msg("Failed to unmarshal property")
+			continue
+		}
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
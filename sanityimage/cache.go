@@ -0,0 +1,84 @@
+package sanityimage
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// lqipTransform is applied when fetching a placeholder: small and heavily
+// compressed, since it's only ever rendered blurred-up behind the real
+// image while that loads.
+var lqipTransform = TransformOptions{Width: 20, Quality: 30, Auto: "format"}
+
+// Cache fetches and memoizes low-quality image placeholders (LQIP) for
+// Sanity image assets for the life of the process. Only successful fetches
+// are cached: a failed fetch is attempted again on the next call, and
+// concurrent first calls for the same asset may each fetch it.
+type Cache struct {
+	client *http.Client
+
+	mu   sync.Mutex
+	lqip map[string]string // asset ref -> data URI
+}
+
+// NewCache returns an empty LQIP cache. A nil client defaults to
+// http.DefaultClient.
+func NewCache(client *http.Client) *Cache {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Cache{client: client, lqip: make(map[string]string)}
+}
+
+// LQIP returns a base64 data URI holding a small, low-quality placeholder
+// for asset (fetched with lqipTransform), fetching and caching it on first
+// successful use.
+func (c *Cache) LQIP(ctx context.Context, project, dataset string, asset Asset) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.lqip[asset.Ref]
+	c.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	target := ResolveURL(project, dataset, asset, lqipTransform)
+	if target == "" {
+		return "", fmt.Errorf("sanityimage: cannot resolve ref %q", asset.Ref)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", fmt.Errorf("sanityimage: building LQIP request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sanityimage: fetching LQIP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("sanityimage: CDN returned %s for %s", resp.Status, target)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("sanityimage: reading LQIP response: %w", err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "image/jpeg"
+	}
+	dataURI := fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data))
+
+	c.mu.Lock()
+	c.lqip[asset.Ref] = dataURI
+	c.mu.Unlock()
+
+	return dataURI, nil
+}
@@ -0,0 +1,92 @@
+// Package sanityimage resolves Sanity CDN image URLs from the opaque
+// asset references stored in Sanity documents, following Sanity's image
+// pipeline conventions (https://www.sanity.io/docs/image-urls).
+package sanityimage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Asset is the subset of a Sanity image asset reference needed to resolve
+// a CDN URL. It's a standalone copy of the root package's Asset type so
+// this package has no dependency on it.
+type Asset struct {
+	Ref  string
+	Type string
+}
+
+// TransformOptions maps to Sanity's image pipeline query parameters.
+type TransformOptions struct {
+	Width   int
+	Height  int
+	Fit     string // e.g. "crop", "clip", "fill", "max", "min", "scale"
+	Quality int
+	Auto    string // usually "format"
+}
+
+const cdnHost = "https://cdn.sanity.io/images"
+
+var assetRefPattern = regexp.MustCompile(`^image-([a-zA-Z0-9]+)-(\d+x\d+)-([a-z]+)$`)
+
+// ResolveURL builds a CDN URL for asset under the given Sanity project and
+// dataset, applying opts as query parameters. It returns "" if asset.Ref
+// doesn't match Sanity's "image-<id>-<dims>-<ext>" reference format.
+func ResolveURL(project, dataset string, asset Asset, opts TransformOptions) string {
+	id, ext, ok := parseImageRef(asset.Ref)
+	if !ok || project == "" || dataset == "" {
+		return ""
+	}
+
+	base := fmt.Sprintf("%s/%s/%s/%s.%s", cdnHost, project, dataset, id, ext)
+
+	q := url.Values{}
+	if opts.Width > 0 {
+		q.Set("w", strconv.Itoa(opts.Width))
+	}
+	if opts.Height > 0 {
+		q.Set("h", strconv.Itoa(opts.Height))
+	}
+	if opts.Fit != "" {
+		q.Set("fit", opts.Fit)
+	}
+	if opts.Quality > 0 {
+		q.Set("q", strconv.Itoa(opts.Quality))
+	}
+	if opts.Auto != "" {
+		q.Set("auto", opts.Auto)
+	}
+
+	if len(q) == 0 {
+		return base
+	}
+	return base + "?" + q.Encode()
+}
+
+// SrcSet builds a srcset attribute value spanning widths, reusing opts for
+// every other transform parameter.
+func SrcSet(project, dataset string, asset Asset, opts TransformOptions, widths []int) string {
+	entries := make([]string, 0, len(widths))
+	for _, w := range widths {
+		o := opts
+		o.Width = w
+		if u := ResolveURL(project, dataset, asset, o); u != "" {
+			entries = append(entries, fmt.Sprintf("%s %dw", u, w))
+		}
+	}
+	return strings.Join(entries, ", ")
+}
+
+// parseImageRef splits a Sanity asset ref like
+// "image-59e1c0a5cdd3e77be6cc8b84d7f6c5b75e1d7eec-1200x800-jpg" into the
+// CDN id (id plus dimensions) and file extension.
+func parseImageRef(ref string) (id, ext string, ok bool) {
+	m := assetRefPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return "", "", false
+	}
+	return fmt.Sprintf("%s-%s", m[1], m[2]), m[3], true
+}
@@ -0,0 +1,47 @@
+package sanityimage
+
+import "testing"
+
+func TestResolveURL(t *testing.T) {
+	asset := Asset{Ref: "image-59e1c0a5cdd3e77be6cc8b84d7f6c5b75e1d7eec-1200x800-jpg", Type: "sanity.imageAsset"}
+
+	t.Run("builds base URL with no options", func(t *testing.T) {
+		got := ResolveURL("proj1", "production", asset, TransformOptions{})
+		want := "https://cdn.sanity.io/images/proj1/production/59e1c0a5cdd3e77be6cc8b84d7f6c5b75e1d7eec-1200x800.jpg"
+		if got != want {
+			t.Errorf("ResolveURL() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("appends transform query params", func(t *testing.T) {
+		got := ResolveURL("proj1", "production", asset, TransformOptions{Width: 400, Fit: "crop", Quality: 75, Auto: "format"})
+		want := "https://cdn.sanity.io/images/proj1/production/59e1c0a5cdd3e77be6cc8b84d7f6c5b75e1d7eec-1200x800.jpg?auto=format&fit=crop&q=75&w=400"
+		if got != want {
+			t.Errorf("ResolveURL() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("unresolvable without project or dataset", func(t *testing.T) {
+		if got := ResolveURL("", "production", asset, TransformOptions{}); got != "" {
+			t.Errorf("ResolveURL() with empty project = %q, want \"\"", got)
+		}
+	})
+
+	t.Run("unresolvable for a non-image asset ref", func(t *testing.T) {
+		bad := Asset{Ref: "file-abc123-pdf"}
+		if got := ResolveURL("proj1", "production", bad, TransformOptions{}); got != "" {
+			t.Errorf("ResolveURL() with malformed ref = %q, want \"\"", got)
+		}
+	})
+}
+
+func TestSrcSet(t *testing.T) {
+	asset := Asset{Ref: "image-59e1c0a5cdd3e77be6cc8b84d7f6c5b75e1d7eec-1200x800-jpg"}
+
+	got := SrcSet("proj1", "production", asset, TransformOptions{Auto: "format"}, []int{400, 800})
+	want := "https://cdn.sanity.io/images/proj1/production/59e1c0a5cdd3e77be6cc8b84d7f6c5b75e1d7eec-1200x800.jpg?auto=format&w=400 400w, " +
+		"https://cdn.sanity.io/images/proj1/production/59e1c0a5cdd3e77be6cc8b84d7f6c5b75e1d7eec-1200x800.jpg?auto=format&w=800 800w"
+	if got != want {
+		t.Errorf("SrcSet() = %q, want %q", got, want)
+	}
+}
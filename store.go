@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PropertyStore holds the last-known-good snapshot of properties fetched
+// from Sanity behind a mutex, along with enough metadata for handlers to
+// serve stale-while-revalidate responses and report freshness on
+// /healthz and /readyz.
+type PropertyStore struct {
+	mu          sync.RWMutex
+	properties  []Property
+	etag        string
+	lastFetched time.Time
+	lastError   error
+}
+
+// NewPropertyStore returns an empty store; it serves zero properties until
+// the first successful fetch populates it.
+func NewPropertyStore() *PropertyStore {
+	return &PropertyStore{}
+}
+
+// Set replaces the cached properties with a freshly fetched snapshot and
+// clears any previously recorded error.
+func (s *PropertyStore) Set(properties []Property) {
+	etag := computeETag(properties)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.properties = properties
+	s.etag = etag
+	s.lastFetched = time.Now()
+	s.lastError = nil
+}
+
+// SetError records a failed fetch attempt without discarding the last
+// known-good snapshot, so handlers keep serving it (stale-while-revalidate).
+func (s *PropertyStore) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err
+}
+
+// Snapshot returns the current properties slice along with its ETag.
+func (s *PropertyStore) Snapshot() ([]Property, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.properties, s.etag
+}
+
+// BySlug returns the property matching slug from the current snapshot.
+func (s *PropertyStore) BySlug(slug string) (Property, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, item := range s.properties {
+		if item.Slug.Current == slug {
+			return item, true
+		}
+	}
+	return Property{}, false
+}
+
+// Status summarizes freshness for /healthz and /readyz.
+type Status struct {
+	LastFetched time.Time `json:"lastFetched"`
+	LastError   string    `json:"lastError,omitempty"`
+	Count       int       `json:"propertyCount"`
+	StaleFor    string    `json:"staleFor,omitempty"`
+}
+
+func (s *PropertyStore) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{
+		LastFetched: s.lastFetched,
+		Count:       len(s.properties),
+	}
+	if s.lastError != nil {
+		status.LastError = s.lastError.Error()
+		if !s.lastFetched.IsZero() {
+			status.StaleFor = time.Since(s.lastFetched).Round(time.Second).String()
+		}
+	}
+	return status
+}
+
+// computeETag hashes the encoded property list so handlers can honor
+// If-None-Match without recomputing a digest on every request.
+func computeETag(properties []Property) string {
+	body, err := json.Marshal(properties)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// Healthz reports process liveness: it always returns 200 once the server
+// is accepting connections, regardless of Sanity freshness.
+func (s *PropertyStore) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Status())
+}
+
+// Readyz reports whether the store has ever been populated. Kubernetes-style
+// readiness probes can use this to hold traffic until the first fetch lands.
+func (s *PropertyStore) Readyz(w http.ResponseWriter, r *http.Request) {
+	status := s.Status()
+	w.Header().Set("Content-Type", "application/json")
+	if status.LastFetched.IsZero() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}